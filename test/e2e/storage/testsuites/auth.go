@@ -17,8 +17,12 @@ limitations under the License.
 package testsuites
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"math/rand"
+	"net/http"
 	"strings"
 	"time"
 
@@ -27,9 +31,11 @@ import (
 	v1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/util/errors"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/kubernetes/test/e2e/framework"
+	e2eevents "k8s.io/kubernetes/test/e2e/framework/events"
 	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
 	e2epv "k8s.io/kubernetes/test/e2e/framework/pv"
 	e2eskipper "k8s.io/kubernetes/test/e2e/framework/skipper"
@@ -54,6 +60,8 @@ func InitCustomAuthTestSuite(patterns []storageframework.TestPattern) storagefra
 func InitAuthTestSuite() storageframework.TestSuite {
 	patterns := []storageframework.TestPattern{
 		storageframework.AuthDynamicPV,
+		storageframework.AuthDynamicPVBlock,
+		storageframework.AuthDynamicSnapshot,
 	}
 	return InitCustomAuthTestSuite(patterns)
 }
@@ -73,9 +81,25 @@ func (s *authTestSuite) SkipUnsupportedTests(driver storageframework.TestDriver,
 	if !ok {
 		e2eskipper.Skipf("Driver %s does not support auth -- skipping", dInfo.Name)
 	}
+
+	if pattern.VolMode == v1.PersistentVolumeBlock && !dInfo.Capabilities[storageframework.CapBlock] {
+		e2eskipper.Skipf("Driver %s doesn't support block volumes -- skipping", dInfo.Name)
+	}
+
+	if pattern.SnapshotType != "" {
+		_, ok = driver.(storageframework.SnapshotableDriver)
+		if !ok {
+			e2eskipper.Skipf("Driver %s doesn't support snapshots -- skipping", dInfo.Name)
+		}
+	}
 }
 
 func (a *authTestSuite) DefineTests(driver storageframework.TestDriver, pattern storageframework.TestPattern) {
+	if pattern.SnapshotType != "" {
+		a.defineSnapshotAuthTests(driver, pattern)
+		return
+	}
+
 	type local struct {
 		driverInfo    *storageframework.DriverInfo
 		config        *storageframework.PerTestConfig
@@ -88,8 +112,19 @@ func (a *authTestSuite) DefineTests(driver storageframework.TestDriver, pattern
 		authSecretData []map[string]string
 		authMatchGroup [][]storageframework.CSIStorageClassAuthParamKey
 
-		// store created secretNames for cleanup
-		secretNames []string
+		// store created secret names for cleanup, keyed by the namespace they live in
+		// (not every secret lives in f.Namespace.Name -- see the templated-secret tests)
+		secretsByNamespace map[string][]string
+		tenantNamespaces   []string
+		// pvcs created directly against a tenant namespace, outside l.resource -- cleanup must
+		// delete these (and any PV they bound) explicitly, the same as the other ad-hoc PVCs
+		// this suite creates, instead of relying on the tenant namespace deletion below to
+		// eventually reclaim a cluster-scoped PV it doesn't even know about.
+		tenantPVCs []*v1.PersistentVolumeClaim
+
+		// non-nil when the driver keeps auth data in Vault instead of Kubernetes Secrets
+		authBackendConfig *storageframework.AuthBackendConfig
+		vaultDataWritten  bool
 	}
 	var l local
 
@@ -98,6 +133,13 @@ func (a *authTestSuite) DefineTests(driver storageframework.TestDriver, pattern
 	f := framework.NewFrameworkWithCustomTimeouts("auth", storageframework.GetDriverTimeouts(driver))
 	f.Timeouts.PodStartShort = 1 * time.Minute
 
+	recordSecret := func(namespace, name string) {
+		if l.secretsByNamespace == nil {
+			l.secretsByNamespace = map[string][]string{}
+		}
+		l.secretsByNamespace[namespace] = append(l.secretsByNamespace[namespace], name)
+	}
+
 	init := func() {
 		l = local{}
 
@@ -119,15 +161,36 @@ func (a *authTestSuite) DefineTests(driver storageframework.TestDriver, pattern
 		testVolumeSizeRange := a.GetTestSuiteInfo().SupportedSizeRange
 
 		l.scAuthParams = authDriver.GetStorageClassAuthParameters(l.config)
-
-		// If authentication is required in the CSI Provisioner phase, create a
-		// secret first for create pvc in CreateVolumeResource()
-		secretName, ok := l.scAuthParams[string(storageframework.CSIProvisionerSecretName)]
-		if ok {
-			err := createOrUpdateSecret(f.ClientSet, f.Namespace.Name,
-				secretName, l.authSecretData[0])
-			framework.ExpectNoError(err, "Failed to create provisioner secret")
+		l.authBackendConfig = authDriver.GetAuthBackendConfig()
+
+		if l.authBackendConfig != nil {
+			// Driver stores auth data in Vault (or a similar KMS) rather than in Kubernetes
+			// Secrets, following the ceph-csi VaultKMS/VaultTokensKMS pattern: credentials for
+			// the whole volume live under a single per-tenant Vault path, and
+			// GetStorageClassAuthParameters() already returns the vaultAddress/vaultBackendPath
+			// parameters the driver bakes into the real StorageClass that CreateVolumeResource
+			// builds below -- the same contract the Kubernetes-Secret branch below relies on for
+			// its *-secret-name parameters. There is nothing for this test to rewrite: it only
+			// needs to put the auth data at the Vault path the driver already points at.
+			err := writeVaultSecretData(l.authBackendConfig, l.authSecretData[0])
+			framework.ExpectNoError(err, "Failed to write vault secret at path: ", l.authBackendConfig.BackendPath)
+			l.vaultDataWritten = true
+		} else {
+			// If authentication is required in the CSI Provisioner phase, create a
+			// secret first for create pvc in CreateVolumeResource()
+			secretName, ok := l.scAuthParams[string(storageframework.CSIProvisionerSecretName)]
+			if ok {
+				err := createOrUpdateSecret(f.ClientSet, f.Namespace.Name,
+					secretName, l.authSecretData[0])
+				framework.ExpectNoError(err, "Failed to create provisioner secret")
+			}
 		}
+
+		// CreateVolumeResource sets l.resource.Pvc.Spec.VolumeMode from pattern.VolMode, and
+		// e2epod.CreateSecPod's MakeSecPod switches a container between VolumeMounts and
+		// VolumeDevices based on that PVC's VolumeMode -- that's what makes the
+		// AuthDynamicPVBlock pattern registered above actually exercise the block-mode
+		// NodeStage/NodePublish paths below, not a second filesystem-mode run.
 		l.resource = storageframework.CreateVolumeResource(driver, l.config, pattern, testVolumeSizeRange)
 
 		l.podConfig = &e2epod.Config{
@@ -138,14 +201,16 @@ func (a *authTestSuite) DefineTests(driver storageframework.TestDriver, pattern
 			ImageID:       e2epod.GetDefaultTestImageID(),
 		}
 
-		// create auth secret define in StorageClass auth params
-		for paramKey, paramValue := range l.scAuthParams {
-			if strings.HasSuffix(paramKey, "secret-name") {
-				secretName := paramValue
-				err := createOrUpdateSecret(f.ClientSet, f.Namespace.Name,
-					secretName, l.authSecretData[0])
-				framework.ExpectNoError(err, "Failed to create secret: ", secretName)
-				l.secretNames = append(l.secretNames, secretName)
+		if l.authBackendConfig == nil {
+			// create auth secret define in StorageClass auth params
+			for paramKey, paramValue := range l.scAuthParams {
+				if strings.HasSuffix(paramKey, "secret-name") {
+					secretName := paramValue
+					err := createOrUpdateSecret(f.ClientSet, f.Namespace.Name,
+						secretName, l.authSecretData[0])
+					framework.ExpectNoError(err, "Failed to create secret: ", secretName)
+					recordSecret(f.Namespace.Name, secretName)
+				}
 			}
 		}
 	}
@@ -153,10 +218,31 @@ func (a *authTestSuite) DefineTests(driver storageframework.TestDriver, pattern
 	cleanup := func() {
 		var errs []error
 
-		for _, secretName := range l.secretNames {
-			err := f.ClientSet.CoreV1().Secrets(f.Namespace.Name).Delete(context.TODO(),
-				secretName, metav1.DeleteOptions{})
-			errs = append(errs, err)
+		for namespace, secretNames := range l.secretsByNamespace {
+			for _, secretName := range secretNames {
+				err := f.ClientSet.CoreV1().Secrets(namespace).Delete(context.TODO(),
+					secretName, metav1.DeleteOptions{})
+				errs = append(errs, err)
+			}
+		}
+
+		if l.vaultDataWritten {
+			errs = append(errs, deleteVaultSecretData(l.authBackendConfig))
+		}
+
+		for _, pvc := range l.tenantPVCs {
+			// Best-effort: the Get is only to learn the bound PV name, if any, and must not
+			// stop the PVC delete below from being attempted even if it errors.
+			if latest, err := f.ClientSet.CoreV1().PersistentVolumeClaims(pvc.Namespace).Get(context.TODO(),
+				pvc.Name, metav1.GetOptions{}); err == nil && latest.Spec.VolumeName != "" {
+				errs = append(errs, e2epv.DeletePersistentVolume(f.ClientSet, latest.Spec.VolumeName))
+			}
+			errs = append(errs, e2epv.DeletePersistentVolumeClaim(f.ClientSet, pvc.Name, pvc.Namespace))
+		}
+
+		for _, tenantNamespace := range l.tenantNamespaces {
+			errs = append(errs, f.ClientSet.CoreV1().Namespaces().Delete(context.TODO(),
+				tenantNamespace, metav1.DeleteOptions{}))
 		}
 
 		if l.resource != nil {
@@ -183,6 +269,15 @@ func (a *authTestSuite) DefineTests(driver storageframework.TestDriver, pattern
 		init()
 		defer cleanup()
 
+		if l.authBackendConfig != nil {
+			// Vault-backed drivers keep the whole tenant's credentials under a single
+			// BackendPath rather than per-stage secret-name Secrets, so there is no per-stage
+			// key in l.scAuthParams to corrupt independently -- "pod creation fails when the
+			// driver is pointed at a Vault path holding wrong auth data" already exercises the
+			// equivalent inconsistency for this backend.
+			e2eskipper.Skipf("Driver %s stores auth data in Vault, not per-stage secrets -- skipping", l.driverInfo.Name)
+		}
+
 		for _, group := range l.authMatchGroup {
 			if len(group) <= 1 {
 				continue
@@ -209,6 +304,119 @@ func (a *authTestSuite) DefineTests(driver storageframework.TestDriver, pattern
 
 	})
 
+	ginkgo.It("pod remounts successfully after auth secret is rotated to a new valid value", func() {
+		init()
+		defer cleanup()
+
+		if l.authBackendConfig != nil {
+			// Vault-backed drivers keep the whole tenant's credentials under a single
+			// BackendPath rather than per-stage secret-name Secrets, so the rotation loop below
+			// (which only touches l.scAuthParams keys ending in "secret-name") would find
+			// nothing to rotate and this test would trivially pass against unchanged data.
+			// "pod binds pvc when the Vault-stored auth secret is rotated between provisioning
+			// and NodeStage" already exercises rotation for this backend.
+			e2eskipper.Skipf("Driver %s stores auth data in Vault, not per-stage secrets -- skipping", l.driverInfo.Name)
+		}
+
+		if len(l.authSecretData) < 2 {
+			e2eskipper.Skipf("authSecretData less than 2, skipping secret rotation test")
+		}
+
+		pod, err := e2epod.CreateSecPod(f.ClientSet, l.podConfig, f.Timeouts.PodStartShort)
+		framework.ExpectNoError(err)
+
+		ginkgo.By("rotating every auth secret referenced by the StorageClass to a new valid value")
+		rotatedSecretData := l.authSecretData[1]
+		for paramKey, paramValue := range l.scAuthParams {
+			if strings.HasSuffix(paramKey, "secret-name") {
+				err := createOrUpdateSecret(f.ClientSet, f.Namespace.Name, paramValue, rotatedSecretData)
+				framework.ExpectNoError(err, "Failed to rotate secret: ", paramValue)
+			}
+		}
+
+		ginkgo.By("deleting the pod so the volume is NodeUnstaged")
+		framework.ExpectNoError(e2epod.DeletePodWithWait(f.ClientSet, pod))
+
+		ginkgo.By("re-creating the pod against the same PVC, expecting NodeStage to re-read the rotated secret")
+		pod, err = e2epod.CreateSecPod(f.ClientSet, l.podConfig, f.Timeouts.PodStartShort)
+		framework.ExpectNoError(err, "expected pod to reach Running after the auth secret was rotated")
+		defer func() {
+			framework.ExpectNoError(e2epod.DeletePodWithWait(f.ClientSet, pod))
+		}()
+	})
+
+	ginkgo.It("pod restart fails when only the node-stage secret is rotated to an inconsistent value", func() {
+		init()
+		defer cleanup()
+
+		if len(l.authSecretData) < 2 {
+			e2eskipper.Skipf("authSecretData less than 2, skipping secret rotation test")
+		}
+
+		nodeStageSecretName, ok := l.scAuthParams[string(storageframework.CSINodeStageSecretName)]
+		if !ok {
+			e2eskipper.Skipf("StorageClass does not set a node-stage secret -- skipping")
+		}
+
+		pod, err := e2epod.CreateSecPod(f.ClientSet, l.podConfig, f.Timeouts.PodStartShort)
+		framework.ExpectNoError(err)
+
+		ginkgo.By("rotating only the node-stage secret to a value inconsistent with the provisioner secret")
+		inconsistentSecretData := makeInconsistentSecretData(l.authSecretData[0])
+		err = createOrUpdateSecret(f.ClientSet, f.Namespace.Name, nodeStageSecretName, inconsistentSecretData)
+		framework.ExpectNoError(err, "Failed to rotate secret: ", nodeStageSecretName)
+
+		ginkgo.By("deleting the pod so the volume is NodeUnstaged")
+		framework.ExpectNoError(e2epod.DeletePodWithWait(f.ClientSet, pod))
+
+		ginkgo.By("re-creating the pod against the same PV, expecting NodeStage to reject the inconsistent secret")
+		pod, err = e2epod.CreateSecPod(f.ClientSet, l.podConfig, f.Timeouts.PodStartShort)
+		framework.ExpectError(err, "expected pod restart to fail with an inconsistent node-stage secret")
+		defer func() {
+			framework.ExpectNoError(e2epod.DeletePodWithWait(f.ClientSet, pod))
+		}()
+	})
+
+	ginkgo.It("pod binds pvc when the Vault-stored auth secret is rotated between provisioning and NodeStage", func() {
+		init()
+		defer cleanup()
+
+		if l.authBackendConfig == nil {
+			e2eskipper.Skipf("Driver %s does not report a Vault-backed auth backend -- skipping", l.driverInfo.Name)
+		}
+
+		if len(l.authSecretData) < 2 {
+			e2eskipper.Skipf("authSecretData less than 2, skipping vault secret rotation test")
+		}
+
+		err := writeVaultSecretData(l.authBackendConfig, l.authSecretData[1])
+		framework.ExpectNoError(err, "Failed to rotate vault secret at path: ", l.authBackendConfig.BackendPath)
+
+		pod, err := e2epod.CreateSecPod(f.ClientSet, l.podConfig, f.Timeouts.PodStartShort)
+		framework.ExpectNoError(err, "expected NodeStage to pick up the rotated Vault secret")
+		defer func() {
+			framework.ExpectNoError(e2epod.DeletePodWithWait(f.ClientSet, pod))
+		}()
+	})
+
+	ginkgo.It("pod creation fails when the driver is pointed at a Vault path holding wrong auth data", func() {
+		init()
+		defer cleanup()
+
+		if l.authBackendConfig == nil {
+			e2eskipper.Skipf("Driver %s does not report a Vault-backed auth backend -- skipping", l.driverInfo.Name)
+		}
+
+		err := writeVaultSecretData(l.authBackendConfig, makeInconsistentSecretData(l.authSecretData[0]))
+		framework.ExpectNoError(err, "Failed to write wrong vault secret at path: ", l.authBackendConfig.BackendPath)
+
+		pod, err := e2epod.CreateSecPod(f.ClientSet, l.podConfig, f.Timeouts.PodStartShort)
+		framework.ExpectError(err)
+		defer func() {
+			framework.ExpectNoError(e2epod.DeletePodWithWait(f.ClientSet, pod))
+		}()
+	})
+
 	ginkgo.It("The no-auth-storageclass and the auth-storageclass can exist at the same time and be correctly used in different pods", func() {
 		init()
 		defer cleanup()
@@ -322,6 +530,198 @@ func (a *authTestSuite) DefineTests(driver storageframework.TestDriver, pattern
 			framework.ExpectNoError(errors.NewAggregate(errs), "while cleaning up resource")
 		}()
 	})
+
+	ginkgo.It("pod binds pvc when auth secrets are resolved via templated namespace/name", func() {
+		init()
+		defer cleanup()
+
+		authDriver, ok := driver.(storageframework.AuthTestDriver)
+		framework.ExpectEqual(ok, true, "Driver not yet implement interface: AuthTestDriver")
+		templatedParams := authDriver.GetTemplatedStorageClassAuthParameters(l.config)
+		if templatedParams == nil {
+			e2eskipper.Skipf("Driver %s does not support templated auth secrets -- skipping", l.driverInfo.Name)
+		}
+
+		ginkgo.By("creating a tenant namespace for the templated PVC")
+		tenantNS, err := framework.CreateTestingNS("auth-tenant", f.ClientSet, nil)
+		framework.ExpectNoError(err)
+		l.tenantNamespaces = append(l.tenantNamespaces, tenantNS.Name)
+
+		usesPVNameTemplate := false
+		for _, v := range templatedParams {
+			if strings.Contains(v, "${pv.name}") {
+				usesPVNameTemplate = true
+				break
+			}
+		}
+
+		ginkgo.By("creating a StorageClass with templated auth parameters")
+		scTemplated := l.resource.Sc.DeepCopy()
+		scTemplated.ObjectMeta = metav1.ObjectMeta{Name: l.resource.Sc.Name + "-templated"}
+		cleanStorageClassAuthParams(scTemplated, l.scAuthParams)
+		for k, v := range templatedParams {
+			scTemplated.Parameters[k] = v
+		}
+
+		if usesPVNameTemplate && scTemplated.VolumeBindingMode != nil &&
+			*scTemplated.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer {
+			// A ${pv.name} node-stage/publish secret name can only be resolved once the pvc
+			// has bound, but under WaitForFirstConsumer binding the pvc doesn't bind until a
+			// pod consumes it -- waiting for bind before creating that pod would deadlock.
+			e2eskipper.Skipf("Driver %s templates a secret with ${pv.name} and uses WaitForFirstConsumer binding -- skipping", l.driverInfo.Name)
+		}
+
+		_, err = f.ClientSet.StorageV1().StorageClasses().Create(context.TODO(), scTemplated, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+
+		ginkgo.By("creating the PVC in the tenant namespace")
+		pvcTenant := l.resource.Pvc.DeepCopy()
+		pvcTenant.ObjectMeta = metav1.ObjectMeta{Name: l.resource.Pvc.Name, Namespace: tenantNS.Name}
+		pvcTenant.Spec.VolumeName = ""
+		pvcTenant.Spec.StorageClassName = &scTemplated.Name
+		pvcTenant, err = f.ClientSet.CoreV1().PersistentVolumeClaims(tenantNS.Name).Create(context.TODO(),
+			pvcTenant, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+		l.tenantPVCs = append(l.tenantPVCs, pvcTenant)
+
+		ginkgo.By("materializing the provisioner-time auth secrets in the tenant namespace under their templated name")
+		for paramKey, paramValue := range templatedParams {
+			if !strings.HasSuffix(paramKey, "secret-name") || strings.Contains(paramValue, "${pv.name}") {
+				continue
+			}
+			secretName := resolveSecretNameTemplate(paramValue, pvcTenant, nil)
+			err := createOrUpdateSecret(f.ClientSet, tenantNS.Name, secretName, l.authSecretData[0])
+			framework.ExpectNoError(err, "Failed to create templated secret: ", secretName)
+			recordSecret(tenantNS.Name, secretName)
+		}
+
+		if usesPVNameTemplate {
+			// Immediate binding (the only mode left after the WaitForFirstConsumer skip above)
+			// binds the pvc as soon as the volume is provisioned -- which only happens once the
+			// provisioner-time secrets above exist -- so the PV name needed to resolve
+			// ${pv.name} is already known before the pod that needs the secret exists.
+			ginkgo.By("waiting for the tenant pvc to bind so ${pv.name} can be resolved")
+			framework.ExpectNoError(e2epv.WaitForPersistentVolumeClaimPhase(v1.ClaimBound, f.ClientSet,
+				tenantNS.Name, pvcTenant.Name, framework.Poll, f.Timeouts.ClaimProvision))
+			pvcTenant, err = f.ClientSet.CoreV1().PersistentVolumeClaims(tenantNS.Name).Get(context.TODO(),
+				pvcTenant.Name, metav1.GetOptions{})
+			framework.ExpectNoError(err)
+			boundPV, err := f.ClientSet.CoreV1().PersistentVolumes().Get(context.TODO(),
+				pvcTenant.Spec.VolumeName, metav1.GetOptions{})
+			framework.ExpectNoError(err)
+
+			ginkgo.By("materializing the ${pv.name} auth secrets in the tenant namespace")
+			for paramKey, paramValue := range templatedParams {
+				if !strings.HasSuffix(paramKey, "secret-name") || !strings.Contains(paramValue, "${pv.name}") {
+					continue
+				}
+				secretName := resolveSecretNameTemplate(paramValue, pvcTenant, boundPV)
+				err := createOrUpdateSecret(f.ClientSet, tenantNS.Name, secretName, l.authSecretData[0])
+				framework.ExpectNoError(err, "Failed to create templated secret: ", secretName)
+				recordSecret(tenantNS.Name, secretName)
+			}
+		}
+
+		tenantPodConfig := &e2epod.Config{
+			NS:            tenantNS.Name,
+			PVCs:          []*v1.PersistentVolumeClaim{pvcTenant},
+			SeLinuxLabel:  e2epod.GetLinuxLabel(),
+			NodeSelection: l.config.ClientNodeSelection,
+			ImageID:       e2epod.GetDefaultTestImageID(),
+		}
+
+		ginkgo.By("creating a pod against the tenant PVC")
+		tenantPod, err := e2epod.CreateSecPod(f.ClientSet, tenantPodConfig, f.Timeouts.PodStartShort)
+		framework.ExpectNoError(err)
+		defer func() {
+			framework.ExpectNoError(e2epod.DeletePodWithWait(f.ClientSet, tenantPod))
+		}()
+	})
+
+	ginkgo.It("pvc provisioning stays Pending when the templated secret is placed in the wrong namespace", func() {
+		init()
+		defer cleanup()
+
+		authDriver, ok := driver.(storageframework.AuthTestDriver)
+		framework.ExpectEqual(ok, true, "Driver not yet implement interface: AuthTestDriver")
+		templatedParams := authDriver.GetTemplatedStorageClassAuthParameters(l.config)
+		if templatedParams == nil {
+			e2eskipper.Skipf("Driver %s does not support templated auth secrets -- skipping", l.driverInfo.Name)
+		}
+
+		ginkgo.By("creating a tenant namespace for the templated PVC")
+		tenantNS, err := framework.CreateTestingNS("auth-tenant", f.ClientSet, nil)
+		framework.ExpectNoError(err)
+		l.tenantNamespaces = append(l.tenantNamespaces, tenantNS.Name)
+
+		scTemplated := l.resource.Sc.DeepCopy()
+		scTemplated.ObjectMeta = metav1.ObjectMeta{Name: l.resource.Sc.Name + "-templated-wrong-ns"}
+		cleanStorageClassAuthParams(scTemplated, l.scAuthParams)
+		for k, v := range templatedParams {
+			scTemplated.Parameters[k] = v
+		}
+
+		if scTemplated.VolumeBindingMode != nil && *scTemplated.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer {
+			// Under WaitForFirstConsumer binding, no pod consumes this pvc, so the external-
+			// provisioner never attempts CreateVolume and there is no secret lookup -- and no
+			// "secret" event -- to wait for below. Skip rather than spuriously time out.
+			e2eskipper.Skipf("Driver %s uses WaitForFirstConsumer binding, which never triggers provisioning without a consumer pod -- skipping", l.driverInfo.Name)
+		}
+
+		_, err = f.ClientSet.StorageV1().StorageClasses().Create(context.TODO(), scTemplated, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+
+		pvcTenant := l.resource.Pvc.DeepCopy()
+		pvcTenant.ObjectMeta = metav1.ObjectMeta{Name: l.resource.Pvc.Name, Namespace: tenantNS.Name}
+		pvcTenant.Spec.VolumeName = ""
+		pvcTenant.Spec.StorageClassName = &scTemplated.Name
+		pvcTenant, err = f.ClientSet.CoreV1().PersistentVolumeClaims(tenantNS.Name).Create(context.TODO(),
+			pvcTenant, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+		l.tenantPVCs = append(l.tenantPVCs, pvcTenant)
+
+		ginkgo.By("materializing the provisioner-time auth secret in the wrong namespace")
+		for paramKey, paramValue := range templatedParams {
+			if !strings.HasSuffix(paramKey, "secret-name") {
+				continue
+			}
+			if strings.Contains(paramValue, "${pv.name}") {
+				// ${pv.name} node-stage/publish secrets are only looked up once the pvc has
+				// bound -- this test's pvc never gets that far, so there is nothing to
+				// meaningfully materialize (in the wrong namespace or otherwise) for them here.
+				continue
+			}
+			secretName := resolveSecretNameTemplate(paramValue, pvcTenant, nil)
+			err := createOrUpdateSecret(f.ClientSet, f.Namespace.Name, secretName, l.authSecretData[0])
+			framework.ExpectNoError(err, "Failed to create templated secret: ", secretName)
+			recordSecret(f.Namespace.Name, secretName)
+		}
+
+		ginkgo.By("expecting the pvc to stay Pending with an event referencing the missing secret")
+		err = e2eevents.WaitTimeoutForEvent(f.ClientSet, tenantNS.Name,
+			fields.Set{"involvedObject.name": pvcTenant.Name}.AsSelector().String(), "secret", f.Timeouts.ClaimProvision)
+		framework.ExpectNoError(err, "expected an event referencing the missing secret")
+
+		pvcTenant, err = f.ClientSet.CoreV1().PersistentVolumeClaims(tenantNS.Name).Get(context.TODO(),
+			pvcTenant.Name, metav1.GetOptions{})
+		framework.ExpectNoError(err)
+		framework.ExpectEqual(pvcTenant.Status.Phase, v1.ClaimPending, "expected pvc to remain Pending")
+	})
+}
+
+// resolveSecretNameTemplate substitutes the ${pvc.namespace}/${pvc.name}/${pv.name} tokens the
+// CSI external-provisioner understands in secret-name StorageClass parameters, so tests can
+// materialize the secret at the same location the driver will look for it. pv may be nil for
+// templates resolved before the pvc has bound, where only the pvc tokens are meaningful.
+func resolveSecretNameTemplate(template string, pvc *v1.PersistentVolumeClaim, pv *v1.PersistentVolume) string {
+	oldnew := []string{
+		"${pvc.namespace}", pvc.Namespace,
+		"${pvc.name}", pvc.Name,
+	}
+	if pv != nil {
+		oldnew = append(oldnew, "${pv.name}", pv.Name)
+	}
+	return strings.NewReplacer(oldnew...).Replace(template)
 }
 
 func createOrUpdateSecret(kubeClient clientset.Interface, namespace, name string, data map[string]string) error {
@@ -372,3 +772,280 @@ func cleanStorageClassAuthParams(sc *storagev1.StorageClass, authParams map[stri
 		delete(sc.Parameters, key)
 	}
 }
+
+// writeVaultSecretData writes data to the driver's per-tenant Vault path using the connection
+// info reported via GetAuthBackendConfig(). It supports both the KV v1 and v2 HTTP APIs,
+// following the ceph-csi VaultKMS/VaultTokensKMS convention of keeping auth data outside of
+// Kubernetes, under the single BackendPath the driver already points at.
+func writeVaultSecretData(backendConfig *storageframework.AuthBackendConfig, data map[string]string) error {
+	body, err := json.Marshal(vaultRequestBody(backendConfig, data))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, vaultSecretURL(backendConfig), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	addVaultHeaders(req, backendConfig)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("vault write to %s returned status %s", backendConfig.BackendPath, resp.Status)
+	}
+	return nil
+}
+
+// deleteVaultSecretData best-effort deletes the secret previously written by writeVaultSecretData.
+func deleteVaultSecretData(backendConfig *storageframework.AuthBackendConfig) error {
+	req, err := http.NewRequest(http.MethodDelete, vaultSecretURL(backendConfig), nil)
+	if err != nil {
+		return err
+	}
+	addVaultHeaders(req, backendConfig)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+func vaultSecretURL(backendConfig *storageframework.AuthBackendConfig) string {
+	if backendConfig.Backend == storageframework.VaultToken {
+		// KV v1's HTTP API is <mount>/<path> -- no separate "data" segment.
+		return fmt.Sprintf("%s/v1/%s/%s", backendConfig.Address, backendConfig.Mount, backendConfig.BackendPath)
+	}
+	// KV v2 (used for the per-tenant VaultTenantToken backend) nests data under
+	// <mount>/data/<path> -- "data" sits right after the mount, not after the full path.
+	return fmt.Sprintf("%s/v1/%s/data/%s", backendConfig.Address, backendConfig.Mount, backendConfig.BackendPath)
+}
+
+func vaultRequestBody(backendConfig *storageframework.AuthBackendConfig, data map[string]string) map[string]interface{} {
+	if backendConfig.Backend == storageframework.VaultToken {
+		return map[string]interface{}{"data": data}
+	}
+	return map[string]interface{}{"data": map[string]interface{}{"data": data}}
+}
+
+func addVaultHeaders(req *http.Request, backendConfig *storageframework.AuthBackendConfig) {
+	req.Header.Set("X-Vault-Token", backendConfig.Token)
+	req.Header.Set("Content-Type", "application/json")
+	if backendConfig.Backend == storageframework.VaultTenantToken {
+		req.Header.Set("X-Vault-Namespace", backendConfig.TenantNamespace)
+	}
+}
+
+// defineSnapshotAuthTests exercises the snapshotter-secret-name / snapshotter-list-secret-name
+// auth parameters that a CSI driver accepts on a VolumeSnapshotClass, mirroring the coverage
+// DefineTests already has for StorageClass auth parameters.
+func (a *authTestSuite) defineSnapshotAuthTests(driver storageframework.TestDriver, pattern storageframework.TestPattern) {
+	type local struct {
+		config        *storageframework.PerTestConfig
+		driverCleanup func()
+
+		resource         *storageframework.VolumeResource
+		snapshotResource *storageframework.SnapshotResource
+		podConfig        *e2epod.Config
+
+		authSecretData      []map[string]string
+		scAuthParams        map[string]string
+		vscAuthParams       map[string]string
+		snapshotSecretNames []string
+	}
+	var l local
+
+	f := framework.NewFrameworkWithCustomTimeouts("auth-snapshot", storageframework.GetDriverTimeouts(driver))
+	f.Timeouts.PodStartShort = 1 * time.Minute
+
+	init := func() {
+		l = local{}
+
+		authDriver, ok := driver.(storageframework.AuthTestDriver)
+		framework.ExpectEqual(ok, true, "Driver not yet implement interface: AuthTestDriver")
+		l.authSecretData = authDriver.GetAuthSecretData()
+		framework.ExpectEqual(len(l.authSecretData) > 0, true,
+			"GetAuthSecretData() must return at least one map in AuthDynamicSnapshot test pattern")
+
+		l.config, l.driverCleanup = driver.PrepareTest(f)
+		testVolumeSizeRange := a.GetTestSuiteInfo().SupportedSizeRange
+		l.scAuthParams = authDriver.GetStorageClassAuthParameters(l.config)
+
+		// create the StorageClass auth secrets before CreateVolumeResource() provisions the pvc
+		for paramKey, paramValue := range l.scAuthParams {
+			if strings.HasSuffix(paramKey, "secret-name") {
+				err := createOrUpdateSecret(f.ClientSet, f.Namespace.Name, paramValue, l.authSecretData[0])
+				framework.ExpectNoError(err, "Failed to create secret: ", paramValue)
+				l.snapshotSecretNames = append(l.snapshotSecretNames, paramValue)
+			}
+		}
+
+		l.resource = storageframework.CreateVolumeResource(driver, l.config, pattern, testVolumeSizeRange)
+
+		l.podConfig = &e2epod.Config{
+			NS:            f.Namespace.Name,
+			PVCs:          []*v1.PersistentVolumeClaim{l.resource.Pvc},
+			SeLinuxLabel:  e2epod.GetLinuxLabel(),
+			NodeSelection: l.config.ClientNodeSelection,
+			ImageID:       e2epod.GetDefaultTestImageID(),
+		}
+
+		l.vscAuthParams = authDriver.GetSnapshotClassAuthParameters(l.config)
+		for paramKey, paramValue := range l.vscAuthParams {
+			if strings.HasSuffix(paramKey, "secret-name") {
+				err := createOrUpdateSecret(f.ClientSet, f.Namespace.Name, paramValue, l.authSecretData[0])
+				framework.ExpectNoError(err, "Failed to create snapshot secret: ", paramValue)
+				l.snapshotSecretNames = append(l.snapshotSecretNames, paramValue)
+			}
+		}
+	}
+
+	cleanup := func() {
+		var errs []error
+
+		for _, secretName := range l.snapshotSecretNames {
+			err := f.ClientSet.CoreV1().Secrets(f.Namespace.Name).Delete(context.TODO(),
+				secretName, metav1.DeleteOptions{})
+			errs = append(errs, err)
+		}
+
+		if l.snapshotResource != nil {
+			errs = append(errs, l.snapshotResource.CleanupResource())
+			l.snapshotResource = nil
+		}
+
+		if l.resource != nil {
+			errs = append(errs, l.resource.CleanupResource())
+			l.resource = nil
+		}
+
+		errs = append(errs, storageutils.TryFunc(l.driverCleanup))
+		framework.ExpectNoError(errors.NewAggregate(errs), "while cleaning up resource")
+	}
+
+	ginkgo.It("pod should bind pvc and volumesnapshot should become ReadyToUse using current snapshotter auth secret data", func() {
+		init()
+		defer cleanup()
+
+		pod, err := e2epod.CreateSecPod(f.ClientSet, l.podConfig, f.Timeouts.PodStartShort)
+		framework.ExpectNoError(err)
+		defer func() {
+			framework.ExpectNoError(e2epod.DeletePodWithWait(f.ClientSet, pod))
+		}()
+
+		l.snapshotResource = storageframework.CreateSnapshotResource(driver.(storageframework.SnapshotableDriver),
+			l.config, pattern, l.resource.Pvc.Name, l.resource.Pvc.Namespace, f.Timeouts, l.vscAuthParams)
+		framework.ExpectNoError(storageutils.WaitForSnapshotReady(f.DynamicClient, l.resource.Pvc.Namespace,
+			l.snapshotResource.Vs.GetName(), framework.Poll, f.Timeouts.SnapshotCreate))
+	})
+
+	ginkgo.It("volumesnapshot creation should fail when a snapshotter secret is inconsistent", func() {
+		init()
+		defer cleanup()
+
+		// l.vscAuthParams only ever holds the two snapshotter-stage keys
+		// (snapshotter-secret-name / snapshotter-list-secret-name); this series never extended
+		// CSIStorageClassAuthParamKey or GetAuthMatchGroup() with snapshotter stages, so there is
+		// no cross-stage match group to route through here the way DefineTests does for
+		// StorageClass auth parameters. Corrupt whichever snapshotter secret-name key(s) the
+		// driver reports directly instead.
+		var secretKeys []string
+		for paramKey := range l.vscAuthParams {
+			if strings.HasSuffix(paramKey, "secret-name") {
+				secretKeys = append(secretKeys, paramKey)
+			}
+		}
+		if len(secretKeys) == 0 {
+			e2eskipper.Skipf("driver does not report any snapshotter secret-name parameters -- skipping")
+		}
+
+		inconsistentKey := secretKeys[rand.Intn(len(secretKeys))]
+		inconsistentSecretName := l.vscAuthParams[inconsistentKey]
+
+		inconsistentSecretData := makeInconsistentSecretData(l.authSecretData[0])
+		err := createOrUpdateSecret(f.ClientSet, f.Namespace.Name, inconsistentSecretName, inconsistentSecretData)
+		framework.ExpectNoError(err, "Failed to create secret: ", inconsistentSecretName)
+
+		pod, err := e2epod.CreateSecPod(f.ClientSet, l.podConfig, f.Timeouts.PodStartShort)
+		framework.ExpectNoError(err)
+		defer func() {
+			framework.ExpectNoError(e2epod.DeletePodWithWait(f.ClientSet, pod))
+		}()
+
+		l.snapshotResource = storageframework.CreateSnapshotResource(driver.(storageframework.SnapshotableDriver),
+			l.config, pattern, l.resource.Pvc.Name, l.resource.Pvc.Namespace, f.Timeouts, l.vscAuthParams)
+		err = storageutils.WaitForSnapshotReady(f.DynamicClient, l.resource.Pvc.Namespace,
+			l.snapshotResource.Vs.GetName(), framework.Poll, f.Timeouts.SnapshotCreate)
+		framework.ExpectError(err, "expected snapshot creation to fail with an inconsistent snapshotter secret")
+	})
+
+	ginkgo.It("a volume restored from a snapshot should still bind in a different StorageClass with its own secret", func() {
+		init()
+		defer cleanup()
+
+		if len(l.authSecretData) < 2 {
+			e2eskipper.Skipf("authSecretData less than 2, skipping restore-with-its-own-secret test")
+		}
+
+		pod, err := e2epod.CreateSecPod(f.ClientSet, l.podConfig, f.Timeouts.PodStartShort)
+		framework.ExpectNoError(err)
+		defer func() {
+			framework.ExpectNoError(e2epod.DeletePodWithWait(f.ClientSet, pod))
+		}()
+
+		l.snapshotResource = storageframework.CreateSnapshotResource(driver.(storageframework.SnapshotableDriver),
+			l.config, pattern, l.resource.Pvc.Name, l.resource.Pvc.Namespace, f.Timeouts, l.vscAuthParams)
+		framework.ExpectNoError(storageutils.WaitForSnapshotReady(f.DynamicClient, l.resource.Pvc.Namespace,
+			l.snapshotResource.Vs.GetName(), framework.Poll, f.Timeouts.SnapshotCreate))
+
+		ginkgo.By("creating a StorageClass with its own, distinct secret for the restore destination")
+		scForRestore := l.resource.Sc.DeepCopy()
+		scForRestore.ObjectMeta = metav1.ObjectMeta{Name: l.resource.Sc.Name + "-restore"}
+		cleanStorageClassAuthParams(scForRestore, l.scAuthParams)
+		if scForRestore.Parameters == nil {
+			scForRestore.Parameters = map[string]string{}
+		}
+		restoreSecretNames := map[string]string{}
+		for paramKey, paramValue := range l.scAuthParams {
+			if strings.HasSuffix(paramKey, "secret-name") {
+				restoreSecretName := paramValue + "-restore"
+				restoreSecretNames[paramKey] = restoreSecretName
+				scForRestore.Parameters[paramKey] = restoreSecretName
+			}
+		}
+		_, err = f.ClientSet.StorageV1().StorageClasses().Create(context.TODO(), scForRestore, metav1.CreateOptions{})
+		framework.ExpectNoError(err)
+
+		for _, restoreSecretName := range restoreSecretNames {
+			err := createOrUpdateSecret(f.ClientSet, f.Namespace.Name, restoreSecretName, l.authSecretData[1])
+			framework.ExpectNoError(err, "Failed to create restore secret: ", restoreSecretName)
+			l.snapshotSecretNames = append(l.snapshotSecretNames, restoreSecretName)
+		}
+
+		ginkgo.By("restoring the snapshot into a new PVC under the StorageClass with its own secret")
+		restoredResource := storageframework.CreateVolumeResourceWithVolumeSnapshot(driver, l.config, pattern,
+			a.GetTestSuiteInfo().SupportedSizeRange, l.snapshotResource.Vs, scForRestore.Name)
+		defer func() {
+			framework.ExpectNoError(restoredResource.CleanupResource())
+		}()
+
+		restoredPodConfig := &e2epod.Config{
+			NS:            f.Namespace.Name,
+			PVCs:          []*v1.PersistentVolumeClaim{restoredResource.Pvc},
+			SeLinuxLabel:  e2epod.GetLinuxLabel(),
+			NodeSelection: l.config.ClientNodeSelection,
+			ImageID:       e2epod.GetDefaultTestImageID(),
+		}
+		restoredPod, err := e2epod.CreateSecPod(f.ClientSet, restoredPodConfig, f.Timeouts.PodStartShort)
+		framework.ExpectNoError(err, "expected pod to bind the restored pvc using the restore StorageClass's own secret")
+		defer func() {
+			framework.ExpectNoError(e2epod.DeletePodWithWait(f.ClientSet, restoredPod))
+		}()
+	})
+}